@@ -5,4 +5,21 @@ type Config interface {
 	Path() string
 	Console() bool
 	Async() bool // 是否异步写入
+
+	SplitByLevel() bool // 是否按级别拆分日志文件；拆分模式下各级别文件直接写入，Async 不生效
+	Director() string   // SplitByLevel 为 true 时，各级别日志文件所在目录
+
+	Format() string // 输出格式："console" 或 "json"，默认 "console"
+
+	// 日志轮转参数，返回零值时使用默认值（分别为 10、30、7）
+	MaxSize() int    // 单个日志文件最大尺寸，单位 MB
+	MaxBackups() int // 保留的旧日志文件最大数量
+	MaxAge() int     // 保留的旧日志文件最大天数
+
+	// NoCompress 为 true 时关闭旧日志文件压缩；零值 false 保持默认的压缩旧日志文件行为
+	NoCompress() bool
+
+	LinkName() string // 指向当前日志文件的符号链接名，留空则不创建
+
+	Prefix() string // 服务名前缀，写入每条日志的 service 字段，便于多服务日志聚合后区分来源
 }
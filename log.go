@@ -1,7 +1,11 @@
 package l
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -16,8 +20,53 @@ var (
 	sugar    *zap.SugaredLogger
 	initOnce sync.Once
 	initMu   sync.RWMutex
+
+	// atomicLevel 是控制台与各文件核心共享的日志等级，Setup 只更新它的值而不替换它，
+	// SetLevel/GetLevel 与 LevelHandler 都基于这个同一个实例生效
+	atomicLevel = zap.NewAtomicLevelAt(zapcore.DebugLevel)
 )
 
+// parseLevel 将字符串解析为 zapcore.Level，未识别时返回 ok=false
+func parseLevel(s string) (zapcore.Level, bool) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return zapcore.DebugLevel, true
+	case "info":
+		return zapcore.InfoLevel, true
+	case "warn", "warning":
+		return zapcore.WarnLevel, true
+	case "error":
+		return zapcore.ErrorLevel, true
+	case "fatal":
+		return zapcore.FatalLevel, true
+	case "panic":
+		return zapcore.PanicLevel, true
+	default:
+		return zapcore.DebugLevel, false
+	}
+}
+
+// SetLevel 在运行时调整日志等级，对控制台与文件核心立即生效，无需重启或重新 Setup
+func SetLevel(levelStr string) error {
+	lvl, ok := parseLevel(levelStr)
+	if !ok {
+		return fmt.Errorf("l: unknown level %q", levelStr)
+	}
+	atomicLevel.SetLevel(lvl)
+	return nil
+}
+
+// GetLevel 返回当前生效的日志等级
+func GetLevel() string {
+	return atomicLevel.Level().String()
+}
+
+// LevelHandler 返回一个兼容 zap GET/PUT JSON 协议的 http.Handler，
+// 可以挂载到调试端口上，供运维在服务运行期间调整日志等级
+func LevelHandler() http.Handler {
+	return atomicLevel
+}
+
 // 自定义时间编码器
 type customTimeEncoder struct {
 	zapcore.TimeEncoder
@@ -65,83 +114,222 @@ func (c customCallerEncoder) EncodeCaller(caller zapcore.EntryCaller, enc zapcor
 	enc.AppendString(callerPath)
 }
 
-func Setup(config Config) error {
-	// 设置日志编码器
+// buildEncoderConfig 根据输出格式构造编码器配置
+// json 格式面向 ELK/Loki 等日志采集系统，使用符合约定的字段名（level/ts/msg/...）、
+// RFC3339Nano 时间戳和小写级别名；console 格式保留现有的紧凑控制台样式
+func buildEncoderConfig(format string) zapcore.EncoderConfig {
+	if format == "json" {
+		return zapcore.EncoderConfig{
+			MessageKey:     "msg",
+			LevelKey:       "level",
+			TimeKey:        "ts",
+			NameKey:        "logger",
+			CallerKey:      "caller",
+			StacktraceKey:  "stacktrace",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+			EncodeDuration: zapcore.SecondsDurationEncoder,
+			EncodeCaller:   customCallerEncoder{}.EncodeCaller,
+		}
+	}
+
 	encoderConfig := zap.NewDevelopmentEncoderConfig()
-	encoderConfig.EncodeTime = customTimeEncoder{}.EncodeTime
-	encoderConfig.EncodeLevel = customLevelEncoder{}.EncodeLevel
 	encoderConfig.EncodeDuration = zapcore.SecondsDurationEncoder
 	encoderConfig.EncodeCaller = customCallerEncoder{}.EncodeCaller
+	encoderConfig.EncodeTime = customTimeEncoder{}.EncodeTime
+	encoderConfig.EncodeLevel = customLevelEncoder{}.EncodeLevel
 	encoderConfig.ConsoleSeparator = " " // 设置控制台输出分隔符为单个空格
+	return encoderConfig
+}
+
+// newFormatEncoder 按格式构造对应的 zapcore.Encoder
+func newFormatEncoder(format string, encoderConfig zapcore.EncoderConfig) zapcore.Encoder {
+	if format == "json" {
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
 
-	// 设置日志等级
+// rotationOpts 是 lumberjack 的轮转参数，由 resolveRotationOpts 从 Config 解析得到（Config 返回零值时取默认值）
+type rotationOpts struct {
+	maxSize    int
+	maxBackups int
+	maxAge     int
+	compress   bool
+}
+
+// resolveRotationOpts 从 Config 读取轮转参数，返回零值的字段使用默认值
+// （MaxSize 10MB、MaxBackups 30、MaxAge 7 天），以保持向后兼容
+func resolveRotationOpts(config Config) rotationOpts {
+	opts := rotationOpts{maxSize: 10, maxBackups: 30, maxAge: 7, compress: true}
+	if config == nil {
+		return opts
+	}
+	if v := config.MaxSize(); v > 0 {
+		opts.maxSize = v
+	}
+	if v := config.MaxBackups(); v > 0 {
+		opts.maxBackups = v
+	}
+	if v := config.MaxAge(); v > 0 {
+		opts.maxAge = v
+	}
+	opts.compress = !config.NoCompress()
+	return opts
+}
+
+// ensureSymlink 在 dir 下创建或刷新一个指向 target 的符号链接 linkName，
+// 供日志采集方用固定路径跟踪当前活跃的日志文件
+func ensureSymlink(dir, linkName, target string) {
+	if linkName == "" {
+		return
+	}
+	linkPath := linkName
+	if !filepath.IsAbs(linkPath) {
+		linkPath = filepath.Join(dir, linkName)
+	}
+	rel, err := filepath.Rel(filepath.Dir(linkPath), target)
+	if err != nil {
+		rel = target
+	}
+	_ = os.Remove(linkPath) // 删除旧的符号链接（如果存在），忽略不存在的情况
+	_ = os.Symlink(rel, linkPath)
+}
+
+func Setup(config Config) error {
+	// 设置输出格式与日志编码器
+	format := "console"
+	if config != nil && config.Format() != "" {
+		format = strings.ToLower(config.Format())
+	}
+	encoderConfig := buildEncoderConfig(format)
+
+	// 设置日志等级：写入共享的 atomicLevel，而不是替换它，
+	// 这样已经通过 With 派生出去的 Logger 也能感知到后续的等级变化
 	level := zapcore.DebugLevel // 默认日志等级
 	if config != nil && config.Level() != "" {
-		switch strings.ToLower(config.Level()) {
-		case "debug":
-			level = zapcore.DebugLevel
-		case "info":
-			level = zapcore.InfoLevel
-		case "warn", "warning":
-			level = zapcore.WarnLevel
-		case "error":
-			level = zapcore.ErrorLevel
-		case "fatal":
-			level = zapcore.FatalLevel
-		case "panic":
-			level = zapcore.PanicLevel
+		if lvl, ok := parseLevel(config.Level()); ok {
+			level = lvl
 		}
 	}
+	atomicLevel.SetLevel(level)
+
+	rotation := resolveRotationOpts(config)
+	var core zapcore.Core
+
+	if config != nil && config.SplitByLevel() && config.Director() != "" {
+		// 按级别拆分：每个级别单独落盘一个文件，再用 Tee 合并成一个核心
+		dir := config.Director()
+		_ = os.MkdirAll(dir, 0o755)
 
-	var writer zapcore.WriteSyncer
-	if config != nil {
-		// 配置日志轮转
-		hook := &lumberjack.Logger{
-			Filename:   config.Path(),
-			MaxSize:    10, // 每个日志文件最大尺寸，单位MB
-			MaxBackups: 30, // 保留的旧日志文件最大数量
-			MaxAge:     7,  // 保留的旧日志文件最大天数
-			Compress:   true,
+		cores := []zapcore.Core{
+			newLevelFileCore(format, encoderConfig, rotation, dir, "server_debug.log", zapcore.DebugLevel),
+			newLevelFileCore(format, encoderConfig, rotation, dir, "server_info.log", zapcore.InfoLevel),
+			newLevelFileCore(format, encoderConfig, rotation, dir, "server_warn.log", zapcore.WarnLevel),
+			newErrorFileCore(format, encoderConfig, rotation, dir, "server_error.log"),
 		}
+		// 拆分模式下最值得被跟踪的是错误日志，LinkName 指向它
+		ensureSymlink(dir, config.LinkName(), filepath.Join(dir, "server_error.log"))
 
 		if config.Console() {
-			// 创建多输出
+			// 控制台同时接收所有级别，不受单文件的精确匹配限制，且随 atomicLevel 动态生效
+			cores = append(cores, zapcore.NewCore(
+				newFormatEncoder(format, encoderConfig),
+				zapcore.AddSync(os.Stdout),
+				atomicLevel,
+			))
+		}
+
+		core = zapcore.NewTee(cores...)
+	} else {
+		var writer zapcore.WriteSyncer
+		if config != nil {
+			_ = os.MkdirAll(filepath.Dir(config.Path()), 0o755)
+
+			// 配置日志轮转
+			hook := &lumberjack.Logger{
+				Filename:   config.Path(),
+				MaxSize:    rotation.maxSize,
+				MaxBackups: rotation.maxBackups,
+				MaxAge:     rotation.maxAge,
+				Compress:   rotation.compress,
+			}
+			ensureSymlink(filepath.Dir(config.Path()), config.LinkName(), config.Path())
+
+			if config.Console() {
+				// 创建多输出
+				writer = zapcore.NewMultiWriteSyncer(
+					zapcore.AddSync(os.Stdout),
+					zapcore.AddSync(hook),
+				)
+			} else {
+				writer = zapcore.AddSync(hook)
+			}
+		} else {
 			writer = zapcore.NewMultiWriteSyncer(
 				zapcore.AddSync(os.Stdout),
-				zapcore.AddSync(hook),
 			)
-		} else {
-			writer = zapcore.AddSync(hook)
 		}
-	} else {
-		writer = zapcore.NewMultiWriteSyncer(
-			zapcore.AddSync(os.Stdout),
-		)
-	}
 
-	if config != nil && config.Async() {
-		writer = &zapcore.BufferedWriteSyncer{
-			WS:   writer,
-			Size: 4096, // 4KB 缓冲区
+		if config != nil && config.Async() {
+			writer = &zapcore.BufferedWriteSyncer{
+				WS:   writer,
+				Size: 4096, // 4KB 缓冲区
+			}
 		}
-	}
 
-	// 创建核心
-	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderConfig),
-		writer, level, // 使用配置的日志等级
-	)
+		// 创建核心，使用 atomicLevel 以便运行时动态调整
+		core = zapcore.NewCore(
+			newFormatEncoder(format, encoderConfig),
+			writer, atomicLevel,
+		)
+	}
 
 	// 创建logger，移除默认的字段分隔符
 	// 使用写锁保护，确保线程安全
 	initMu.Lock()
 	logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	if config != nil && config.Prefix() != "" {
+		// 为每条日志打上 service 字段，便于多服务聚合后按来源区分
+		logger = logger.WithOptions(zap.Fields(zap.String("service", config.Prefix())))
+	}
 	sugar = logger.Sugar()
 	initMu.Unlock()
 
 	return nil
 }
 
+// newLevelFileCore 构造一个只接收单一级别日志的文件核心，并随 atomicLevel 动态生效
+func newLevelFileCore(format string, encoderConfig zapcore.EncoderConfig, rotation rotationOpts, dir, name string, exact zapcore.Level) zapcore.Core {
+	hook := &lumberjack.Logger{
+		Filename:   filepath.Join(dir, name),
+		MaxSize:    rotation.maxSize,
+		MaxBackups: rotation.maxBackups,
+		MaxAge:     rotation.maxAge,
+		Compress:   rotation.compress,
+	}
+	enabler := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l == exact && atomicLevel.Enabled(l)
+	})
+	return zapcore.NewCore(newFormatEncoder(format, encoderConfig), zapcore.AddSync(hook), enabler)
+}
+
+// newErrorFileCore 构造错误日志文件核心，接收 Error 及以上级别
+func newErrorFileCore(format string, encoderConfig zapcore.EncoderConfig, rotation rotationOpts, dir, name string) zapcore.Core {
+	hook := &lumberjack.Logger{
+		Filename:   filepath.Join(dir, name),
+		MaxSize:    rotation.maxSize,
+		MaxBackups: rotation.maxBackups,
+		MaxAge:     rotation.maxAge,
+		Compress:   rotation.compress,
+	}
+	enabler := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= zapcore.ErrorLevel && atomicLevel.Enabled(l)
+	})
+	return zapcore.NewCore(newFormatEncoder(format, encoderConfig), zapcore.AddSync(hook), enabler)
+}
+
 func Unsetup() error {
 	return nil
 }
@@ -181,7 +369,7 @@ func ensureInitialized() {
 		core := zapcore.NewCore(
 			zapcore.NewConsoleEncoder(encoderConfig),
 			zapcore.AddSync(os.Stdout),
-			zapcore.DebugLevel,
+			atomicLevel,
 		)
 
 		logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
@@ -248,3 +436,88 @@ func Panicf(message string, args ...interface{}) {
 	ensureInitialized()
 	sugar.Panicf(message, args...)
 }
+
+func Debugw(msg string, keysAndValues ...interface{}) {
+	ensureInitialized()
+	sugar.Debugw(msg, keysAndValues...)
+}
+
+func Infow(msg string, keysAndValues ...interface{}) {
+	ensureInitialized()
+	sugar.Infow(msg, keysAndValues...)
+}
+
+func Warnw(msg string, keysAndValues ...interface{}) {
+	ensureInitialized()
+	sugar.Warnw(msg, keysAndValues...)
+}
+
+func Errorw(msg string, keysAndValues ...interface{}) {
+	ensureInitialized()
+	sugar.Errorw(msg, keysAndValues...)
+}
+
+// Logger 是携带固定字段的作用域化日志器，由 With 派生而来
+type Logger struct {
+	s *zap.SugaredLogger
+}
+
+// With 返回一个携带给定字段的 *Logger，不影响全局 sugar
+func With(fields ...zap.Field) *Logger {
+	ensureInitialized()
+	initMu.RLock()
+	base := logger
+	initMu.RUnlock()
+	return &Logger{s: base.With(fields...).Sugar()}
+}
+
+func (l *Logger) Debug(args ...interface{}) { l.s.Debug(args...) }
+func (l *Logger) Info(args ...interface{})  { l.s.Info(args...) }
+func (l *Logger) Warn(args ...interface{})  { l.s.Warn(args...) }
+func (l *Logger) Error(args ...interface{}) { l.s.Error(args...) }
+func (l *Logger) Fatal(args ...interface{}) { l.s.Fatal(args...) }
+func (l *Logger) Panic(args ...interface{}) { l.s.Panic(args...) }
+
+func (l *Logger) Debugf(message string, args ...interface{}) { l.s.Debugf(message, args...) }
+func (l *Logger) Infof(message string, args ...interface{})  { l.s.Infof(message, args...) }
+func (l *Logger) Warnf(message string, args ...interface{})  { l.s.Warnf(message, args...) }
+func (l *Logger) Errorf(message string, args ...interface{}) { l.s.Errorf(message, args...) }
+func (l *Logger) Fatalf(message string, args ...interface{}) { l.s.Fatalf(message, args...) }
+func (l *Logger) Panicf(message string, args ...interface{}) { l.s.Panicf(message, args...) }
+
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) { l.s.Debugw(msg, keysAndValues...) }
+func (l *Logger) Infow(msg string, keysAndValues ...interface{})  { l.s.Infow(msg, keysAndValues...) }
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{})  { l.s.Warnw(msg, keysAndValues...) }
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) { l.s.Errorw(msg, keysAndValues...) }
+
+// Named 返回一个附加了 name 作为 logger 名称的 *Logger（例如模块名），不影响全局 sugar
+func Named(name string) *Logger {
+	ensureInitialized()
+	initMu.RLock()
+	base := logger
+	initMu.RUnlock()
+	return &Logger{s: base.Named(name).Sugar()}
+}
+
+// defaultLogger 返回包装了全局 sugar 的 *Logger，供 Ctx 在上下文中找不到 logger 时兜底使用
+func defaultLogger() *Logger {
+	ensureInitialized()
+	initMu.RLock()
+	defer initMu.RUnlock()
+	return &Logger{s: sugar}
+}
+
+type loggerCtxKey struct{}
+
+// NewContext 将 logger 存入 ctx，供下游通过 Ctx 取出，实现按请求派生的日志链路
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
+}
+
+// Ctx 从 ctx 中取出通过 NewContext 存入的 *Logger；不存在时回退到全局 logger
+func Ctx(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*Logger); ok && logger != nil {
+		return logger
+	}
+	return defaultLogger()
+}